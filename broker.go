@@ -0,0 +1,55 @@
+/*
+Author: Paul Côté
+Last Change Author: Paul Côté
+Last Date Changed: 2022/11/02
+*/
+
+package laniakea_sdk
+
+import (
+	"context"
+
+	"github.com/SSSOC-CAN/laniakea-plugin-sdk/proto"
+	bg "github.com/SSSOCPaulCote/blunderguard"
+	"github.com/hashicorp/go-plugin"
+)
+
+const (
+	ErrBrokerNotAvailable = bg.Error("plugin was not served with a gRPC broker")
+)
+
+// registrarBrokerID is the broker stream ID the host reserves to serve its
+// plugin Registrar on, so a plugin can resolve another loaded plugin's name
+// to its own broker ID before dialing it directly.
+const registrarBrokerID = 1
+
+// brokerSetter is implemented by Base types that hold onto the
+// plugin.GRPCBroker they were constructed with, letting DatasourcePlugin
+// and ControllerPlugin thread the broker through to DialPlugin without the
+// Datasource/Controller interfaces needing to know about it.
+type brokerSetter interface {
+	setBroker(b *plugin.GRPCBroker)
+}
+
+// dialPlugin resolves name to a broker ID via the host's Registrar service
+// on registrarBrokerID, then dials that ID directly, bypassing the host
+// process for the resulting Datasource stream.
+func dialPlugin(broker *plugin.GRPCBroker, name string) (Datasource, error) {
+	if broker == nil {
+		return nil, ErrBrokerNotAvailable
+	}
+	regConn, err := broker.Dial(registrarBrokerID)
+	if err != nil {
+		return nil, err
+	}
+	defer regConn.Close()
+	resp, err := proto.NewRegistrarClient(regConn).ResolvePlugin(context.Background(), &proto.PluginName{Name: name})
+	if err != nil {
+		return nil, err
+	}
+	conn, err := broker.Dial(resp.BrokerId)
+	if err != nil {
+		return nil, err
+	}
+	return &DatasourceGRPCClient{client: proto.NewDatasourceClient(conn)}, nil
+}
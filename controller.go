@@ -8,10 +8,14 @@ package laniakea_sdk
 
 import (
 	"context"
+	"crypto/ed25519"
 	"errors"
 	"io"
+	"time"
 
+	"github.com/SSSOC-CAN/laniakea-plugin-sdk/data"
 	"github.com/SSSOC-CAN/laniakea-plugin-sdk/proto"
+	"github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/go-version"
 )
 
@@ -31,20 +35,27 @@ func (c *ControllerGRPCClient) Stop() error {
 	return nil
 }
 
-// Command implements the Controller interface method Command
-func (c *ControllerGRPCClient) Command(f *proto.Frame) (chan *proto.Frame, error) {
-	stream, err := c.client.Command(context.Background(), f)
+// Command implements the Controller interface method Command as a
+// bidirectional stream: frames read from in are forwarded to the plugin as
+// they arrive, and the plugin's responses are delivered on the returned
+// channel. Closing in half-closes the client's send side; the returned
+// channel closes once the plugin closes its send side or the stream ends.
+// ctx governs the whole session — cancelling it tears down a stalled
+// stream instead of leaving the caller blocked waiting on the plugin.
+func (c *ControllerGRPCClient) Command(ctx context.Context, in <-chan *proto.Frame) (<-chan *proto.Frame, error) {
+	stream, err := c.client.Command(ctx)
 	if err != nil {
 		return nil, err
 	}
-	// sometimes the first stream receive is an error
+	go func() {
+		for frame := range in {
+			if err := stream.Send(frame); err != nil {
+				break
+			}
+		}
+		stream.CloseSend()
+	}()
 	frameChan := make(chan *proto.Frame)
-	frame, err := stream.Recv()
-	if frame == nil || err == io.EOF {
-		return nil, err
-	} else if err != nil {
-		return nil, err
-	}
 	go func() {
 		defer close(frameChan)
 		for {
@@ -53,7 +64,7 @@ func (c *ControllerGRPCClient) Command(f *proto.Frame) (chan *proto.Frame, error
 				return
 			}
 			if err != nil {
-				break
+				return
 			}
 			frameChan <- frame
 		}
@@ -61,6 +72,79 @@ func (c *ControllerGRPCClient) Command(f *proto.Frame) (chan *proto.Frame, error
 	return frameChan, nil
 }
 
+// CommandArrow implements the Controller interface method CommandArrow.
+// Chunked payloads sent by the plugin's SendFrame helper are reassembled
+// here so callers always receive one complete Arrow IPC stream per frame.
+// Chunks are buffered per Source so frames from different sources can
+// interleave on the stream without corrupting one another.
+func (c *ControllerGRPCClient) CommandArrow(f *proto.ArrowFrame) (chan *proto.ArrowFrame, error) {
+	stream, err := c.client.CommandArrow(context.Background(), f)
+	if err != nil {
+		return nil, err
+	}
+	frameChan := make(chan *proto.ArrowFrame)
+	go func() {
+		defer close(frameChan)
+		bufs := make(map[string][]byte)
+		for {
+			chunk, err := stream.Recv()
+			if chunk == nil || err == io.EOF {
+				return
+			}
+			if err != nil {
+				break
+			}
+			bufs[chunk.Source] = append(bufs[chunk.Source], chunk.Payload...)
+			if !chunk.Final {
+				continue
+			}
+			frameChan <- &proto.ArrowFrame{
+				Source:    chunk.Source,
+				Timestamp: chunk.Timestamp,
+				Payload:   bufs[chunk.Source],
+				Final:     true,
+			}
+			delete(bufs, chunk.Source)
+		}
+	}()
+	return frameChan, nil
+}
+
+// CallResource implements the Controller interface method CallResource.
+// Response chunks are reassembled into a single proto.ResourceResponse so
+// callers don't need to know the wire-level chunking.
+func (c *ControllerGRPCClient) CallResource(req *proto.ResourceRequest) (chan *proto.ResourceResponse, error) {
+	stream, err := c.client.CallResource(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	respChan := make(chan *proto.ResourceResponse)
+	go func() {
+		defer close(respChan)
+		var resp *proto.ResourceResponse
+		for {
+			chunk, err := stream.Recv()
+			if chunk == nil || err == io.EOF {
+				return
+			}
+			if err != nil {
+				break
+			}
+			if resp == nil {
+				resp = &proto.ResourceResponse{Status: chunk.Status, Headers: chunk.Headers}
+			}
+			resp.Body = append(resp.Body, chunk.Body...)
+			if !chunk.Final {
+				continue
+			}
+			resp.Final = true
+			respChan <- resp
+			resp = nil
+		}
+	}()
+	return respChan, nil
+}
+
 // PushVersion implements the Controller interface method PushVersion
 func (c *ControllerGRPCClient) PushVersion(versionNumber string) error {
 	_, err := c.client.PushVersion(context.Background(), &proto.VersionNumber{Version: versionNumber})
@@ -79,21 +163,74 @@ func (c *ControllerGRPCClient) GetVersion() (string, error) {
 	return resp.Version, nil
 }
 
+// GetManifest implements the Controller interface method GetManifest
+func (c *ControllerGRPCClient) GetManifest() (*proto.Manifest, error) {
+	return c.client.GetManifest(context.Background(), &proto.Empty{})
+}
+
 // Stop implements the Controller gRPC server interface
 func (s *ControllerGRPCServer) Stop(ctx context.Context, _ *proto.Empty) (*proto.Empty, error) {
 	err := s.Impl.Stop()
 	return &proto.Empty{}, err
 }
 
-// Command implements the Controller gRPC server interface
-func (s *ControllerGRPCServer) Command(req *proto.Frame, stream proto.Controller_CommandServer) error {
-	frameChan, err := s.Impl.Command(req)
+// Command implements the Controller gRPC server interface as a
+// bidirectional stream: frames received from the client are forwarded onto
+// a channel the plugin's Command handler consumes, while the handler's
+// responses are streamed back to the client as they're produced.
+func (s *ControllerGRPCServer) Command(stream proto.Controller_CommandServer) error {
+	in := make(chan *proto.Frame)
+	go func() {
+		defer close(in)
+		for {
+			frame, err := stream.Recv()
+			if frame == nil || err == io.EOF {
+				return
+			}
+			if err != nil {
+				return
+			}
+			select {
+			case in <- frame:
+			case <-stream.Context().Done():
+				return
+			}
+		}
+	}()
+	out, err := s.Impl.Command(stream.Context(), in)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case frame, ok := <-out:
+			if !ok {
+				return nil
+			}
+			if err := stream.Send(frame); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			if errors.Is(stream.Context().Err(), context.Canceled) {
+				return nil
+			}
+			return stream.Context().Err()
+		}
+	}
+}
+
+// CommandArrow implements the Controller gRPC server interface
+func (s *ControllerGRPCServer) CommandArrow(req *proto.ArrowFrame, stream proto.Controller_CommandArrowServer) error {
+	frameChan, err := s.Impl.CommandArrow(req)
 	if err != nil {
 		return err
 	}
 	for {
 		select {
 		case frame := <-frameChan:
+			if frame == nil {
+				return nil
+			}
 			if err := stream.Send(frame); err != nil {
 				return err
 			}
@@ -106,6 +243,30 @@ func (s *ControllerGRPCServer) Command(req *proto.Frame, stream proto.Controller
 	}
 }
 
+// CallResource implements the Controller gRPC server interface
+func (s *ControllerGRPCServer) CallResource(req *proto.ResourceRequest, stream proto.Controller_CallResourceServer) error {
+	respChan, err := s.Impl.CallResource(req)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case resp := <-respChan:
+			if resp == nil {
+				return nil
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			if errors.Is(stream.Context().Err(), context.Canceled) {
+				return nil
+			}
+			return stream.Context().Err()
+		}
+	}
+}
+
 // PushVersion implements the Controller gRPC server interface
 func (s *ControllerGRPCServer) PushVersion(ctx context.Context, req *proto.VersionNumber) (*proto.Empty, error) {
 	err := s.Impl.PushVersion(req.Version)
@@ -118,12 +279,100 @@ func (s *ControllerGRPCServer) GetVersion(ctx context.Context, _ *proto.Empty) (
 	return &proto.VersionNumber{Version: v}, err
 }
 
+// GetManifest implements the Controller gRPC server interface
+func (s *ControllerGRPCServer) GetManifest(ctx context.Context, _ *proto.Empty) (*proto.Manifest, error) {
+	return s.Impl.GetManifest()
+}
+
 // ControllerBase is a rough implementation of the Controller interface
 // It implements the PushVersion and GetVersion functions for convenience
 type ControllerBase struct {
 	version               string
 	laniVersionConstraint version.Constraints
 	laniVersion           string
+	resourceMux           *ResourceMux
+	signingKey            ed25519.PrivateKey
+	manifest              *PluginManifest
+	// Broker is the gRPC broker go-plugin handed this plugin at startup. It
+	// is populated automatically by ControllerPlugin.GRPCServer; use
+	// DialPlugin instead of dialing it directly where possible.
+	Broker *plugin.GRPCBroker
+}
+
+// setBroker implements brokerSetter
+func (b *ControllerBase) setBroker(broker *plugin.GRPCBroker) {
+	b.Broker = broker
+}
+
+// DialPlugin opens a direct gRPC connection to another loaded plugin named
+// name via Broker, without routing frames through the host process. This
+// enables compositional pipelines, e.g. a filter controller subscribing
+// directly to a sensor datasource's StartRecord stream.
+func (b *ControllerBase) DialPlugin(name string) (Datasource, error) {
+	return dialPlugin(b.Broker, name)
+}
+
+// LoadSigningKey reads the ed25519 private key at path and stores it for
+// SignManifest/GetManifest to sign this plugin's PluginManifest with.
+func (b *ControllerBase) LoadSigningKey(path string) error {
+	key, err := loadSigningKey(path)
+	if err != nil {
+		return err
+	}
+	b.signingKey = key
+	return nil
+}
+
+// SetManifest stores the PluginManifest that SignManifest/GetManifest sign
+// and return.
+func (b *ControllerBase) SetManifest(m PluginManifest) {
+	b.manifest = &m
+}
+
+// SignManifest signs the PluginManifest set via SetManifest with the key
+// loaded via LoadSigningKey.
+func (b *ControllerBase) SignManifest() (*proto.Manifest, error) {
+	if b.manifest == nil {
+		return nil, ErrManifestNotSet
+	}
+	if b.signingKey == nil {
+		return nil, ErrSigningKeyNotSet
+	}
+	return signManifest(b.manifest, b.signingKey), nil
+}
+
+// GetManifest implements the Controller interface method GetManifest
+func (b *ControllerBase) GetManifest() (*proto.Manifest, error) {
+	return b.SignManifest()
+}
+
+// HandleResource registers handler to serve CallResource requests matching
+// method and path, e.g. base.HandleResource("GET", "/config", handler).
+func (b *ControllerBase) HandleResource(method, path string, handler ResourceHandler) {
+	if b.resourceMux == nil {
+		b.resourceMux = NewResourceMux()
+	}
+	b.resourceMux.Handle(method, path, handler)
+}
+
+// CallResource implements the Controller interface method CallResource by
+// dispatching req through the ResourceMux populated via HandleResource, and
+// chunking the response across the returned channel. Embedders that need custom dispatch can
+// shadow this method.
+func (b *ControllerBase) CallResource(req *proto.ResourceRequest) (chan *proto.ResourceResponse, error) {
+	if b.resourceMux == nil {
+		return nil, ErrResourceNotFound
+	}
+	resp, err := b.resourceMux.ServeResource(req)
+	if err != nil {
+		return nil, err
+	}
+	respChan := make(chan *proto.ResourceResponse)
+	go func() {
+		defer close(respChan)
+		sendResourceResponse(respChan, resp)
+	}()
+	return respChan, nil
 }
 
 // SetPluginVersion sets the plugin version string
@@ -131,6 +380,56 @@ func (b *ControllerBase) SetPluginVersion(verStr string) {
 	b.version = verStr
 }
 
+// ServeCommand runs handler against in and a freshly created output
+// channel, in its own goroutine, so a Controller's Command method can be
+// implemented as a one-liner:
+//
+//	func (e *Example) Command(ctx context.Context, in <-chan *proto.Frame) (<-chan *proto.Frame, error) {
+//		return e.ServeCommand(ctx, in, func(ctx context.Context, in <-chan *proto.Frame, out chan<- *proto.Frame) error {
+//			...
+//		})
+//	}
+//
+// handler is cancelled, via the ctx it receives, when ctx is cancelled or
+// handler returns; out is closed for the caller automatically. handler
+// should exit when in is closed or ctx is done.
+func (b *ControllerBase) ServeCommand(ctx context.Context, in <-chan *proto.Frame, handler func(ctx context.Context, in <-chan *proto.Frame, out chan<- *proto.Frame) error) (<-chan *proto.Frame, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	out := make(chan *proto.Frame)
+	go func() {
+		defer cancel()
+		defer close(out)
+		handler(ctx, in, out)
+	}()
+	return out, nil
+}
+
+// SendFrame marshals f to its Arrow IPC representation and pushes it onto
+// out as one or more proto.ArrowFrame chunks, splitting large batches so no
+// single message exceeds gRPC's default max message size. Only the final
+// chunk has Final set; the client reassembles chunks sharing a Source
+// before handing a complete frame to callers.
+func (b *ControllerBase) SendFrame(out chan<- *proto.ArrowFrame, f *data.Frame) error {
+	buf, err := f.MarshalArrow()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	for i := 0; i < len(buf); i += arrowFrameChunkSize {
+		end := i + arrowFrameChunkSize
+		if end > len(buf) {
+			end = len(buf)
+		}
+		out <- &proto.ArrowFrame{
+			Source:    f.Name,
+			Timestamp: now,
+			Payload:   buf[i:end],
+			Final:     end == len(buf),
+		}
+	}
+	return nil
+}
+
 // SetVersionConstraints sets the version constraints on Laniakea
 func (b *ControllerBase) SetRequiredVersion(verStr string) error {
 	constraints, err := version.NewConstraint(verStr)
@@ -154,6 +453,14 @@ func (b *ControllerBase) GetVersion() (string, error) {
 	return b.version, nil
 }
 
+// NegotiatedProtocolVersion returns the wire protocol version this plugin
+// process negotiated with the host. Plugins served with ServeVersioned get
+// the highest version both sides advertised; plugins served with plain
+// plugin.Serve always get HandshakeConfig.ProtocolVersion.
+func (b *ControllerBase) NegotiatedProtocolVersion() uint {
+	return negotiatedProtocolVersion
+}
+
 // PushVersion sets the required laniakea version
 func (b *ControllerBase) PushVersion(versionNumber string) error {
 	laniV, err := version.NewVersion(versionNumber)
@@ -0,0 +1,177 @@
+/*
+Author: Paul Côté
+Last Change Author: Paul Côté
+Last Date Changed: 2022/09/02
+*/
+
+// Package data provides a typed, column-oriented Frame that plugins can use
+// to stream numeric data as Apache Arrow IPC record batches instead of
+// hand-rolling a serialization for proto.Frame's opaque Payload. A Frame
+// marshals to a single Arrow IPC stream (one schema message followed by one
+// record batch) and back, so it is suitable for both the plugin and host
+// side of the StartRecordArrow/CommandArrow RPCs.
+package data
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/apache/arrow/go/arrow"
+	"github.com/apache/arrow/go/arrow/array"
+	"github.com/apache/arrow/go/arrow/ipc"
+	"github.com/apache/arrow/go/arrow/memory"
+)
+
+// FieldType identifies the Arrow data type backing a Field's values.
+type FieldType int
+
+const (
+	// Float64Type backs a Field of float64 samples.
+	Float64Type FieldType = iota
+	// Int64Type backs a Field of int64 samples.
+	Int64Type
+	// TimestampNsType backs a Field of nanosecond-precision timestamps.
+	TimestampNsType
+)
+
+// arrowType returns the Arrow DataType a FieldType is encoded as.
+func (t FieldType) arrowType() (arrow.DataType, error) {
+	switch t {
+	case Float64Type:
+		return arrow.PrimitiveTypes.Float64, nil
+	case Int64Type:
+		return arrow.PrimitiveTypes.Int64, nil
+	case TimestampNsType:
+		return arrow.FixedWidthTypes.Timestamp_ns, nil
+	default:
+		return nil, fmt.Errorf("data: unknown FieldType %d", t)
+	}
+}
+
+// Field is a single named column of a Frame. Exactly one of Floats/Ints
+// should be populated, matching Type.
+type Field struct {
+	Name   string
+	Type   FieldType
+	Floats []float64
+	Ints   []int64
+}
+
+// NewField constructs a Field of the given type from vals, which must be a
+// []float64 for Float64Type or a []int64 for Int64Type/TimestampNsType.
+func NewField(name string, typ FieldType, vals interface{}) *Field {
+	f := &Field{Name: name, Type: typ}
+	switch v := vals.(type) {
+	case []float64:
+		f.Floats = v
+	case []int64:
+		f.Ints = v
+	}
+	return f
+}
+
+// len returns the number of samples in the Field.
+func (f *Field) len() int {
+	if f.Floats != nil {
+		return len(f.Floats)
+	}
+	return len(f.Ints)
+}
+
+// Frame is a column-oriented table of equal-length Fields, analogous to an
+// Arrow RecordBatch with a name attached.
+type Frame struct {
+	Name   string
+	Fields []*Field
+}
+
+// NewFrame constructs a Frame from the given fields. All fields must have
+// the same length; this is checked at MarshalArrow time.
+func NewFrame(name string, fields ...*Field) *Frame {
+	return &Frame{Name: name, Fields: fields}
+}
+
+// MarshalArrow encodes the Frame as an Arrow IPC stream: a schema message
+// followed by a single record batch holding all of the Frame's samples.
+func (f *Frame) MarshalArrow() ([]byte, error) {
+	if len(f.Fields) == 0 {
+		return nil, fmt.Errorf("data: frame %q has no fields", f.Name)
+	}
+	arrowFields := make([]arrow.Field, len(f.Fields))
+	rows := f.Fields[0].len()
+	for i, field := range f.Fields {
+		if field.len() != rows {
+			return nil, fmt.Errorf("data: field %q has %d samples, want %d", field.Name, field.len(), rows)
+		}
+		typ, err := field.Type.arrowType()
+		if err != nil {
+			return nil, err
+		}
+		arrowFields[i] = arrow.Field{Name: field.Name, Type: typ}
+	}
+	schema := arrow.NewSchema(arrowFields, nil)
+	pool := memory.NewGoAllocator()
+	b := array.NewRecordBuilder(pool, schema)
+	defer b.Release()
+	for i, field := range f.Fields {
+		switch field.Type {
+		case Float64Type:
+			b.Field(i).(*array.Float64Builder).AppendValues(field.Floats, nil)
+		case Int64Type:
+			b.Field(i).(*array.Int64Builder).AppendValues(field.Ints, nil)
+		case TimestampNsType:
+			ts := make([]arrow.Timestamp, len(field.Ints))
+			for j, v := range field.Ints {
+				ts[j] = arrow.Timestamp(v)
+			}
+			b.Field(i).(*array.TimestampBuilder).AppendValues(ts, nil)
+		}
+	}
+	rec := b.NewRecord()
+	defer rec.Release()
+	var buf bytes.Buffer
+	w := ipc.NewWriter(&buf, ipc.WithSchema(schema), ipc.WithAllocator(pool))
+	if err := w.Write(rec); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalFrame decodes an Arrow IPC stream produced by MarshalArrow back
+// into a Frame, reassembling the record batch's columns into Fields.
+func UnmarshalFrame(name string, b []byte) (*Frame, error) {
+	r, err := ipc.NewReader(bytes.NewReader(b), ipc.WithAllocator(memory.NewGoAllocator()))
+	if err != nil {
+		return nil, err
+	}
+	defer r.Release()
+	if !r.Next() {
+		if err := r.Err(); err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("data: arrow IPC stream for %q has no record batch", name)
+	}
+	rec := r.Record()
+	fields := make([]*Field, rec.NumCols())
+	for i, col := range rec.Columns() {
+		field := rec.Schema().Field(i)
+		switch arr := col.(type) {
+		case *array.Float64:
+			fields[i] = NewField(field.Name, Float64Type, append([]float64(nil), arr.Float64Values()...))
+		case *array.Int64:
+			fields[i] = NewField(field.Name, Int64Type, append([]int64(nil), arr.Int64Values()...))
+		case *array.Timestamp:
+			vals := make([]int64, arr.Len())
+			for j := 0; j < arr.Len(); j++ {
+				vals[j] = int64(arr.Value(j))
+			}
+			fields[i] = NewField(field.Name, TimestampNsType, vals)
+		default:
+			return nil, fmt.Errorf("data: unsupported arrow column type %T for field %q", col, field.Name)
+		}
+	}
+	return NewFrame(name, fields...), nil
+}
@@ -8,14 +8,23 @@ package laniakea_sdk
 
 import (
 	"context"
+	"crypto/ed25519"
 	"errors"
 	"io"
+	"time"
 
+	"github.com/SSSOC-CAN/laniakea-plugin-sdk/data"
 	"github.com/SSSOC-CAN/laniakea-plugin-sdk/proto"
 	bg "github.com/SSSOCPaulCote/blunderguard"
+	"github.com/hashicorp/go-plugin"
 	"github.com/hashicorp/go-version"
 )
 
+// arrowFrameChunkSize is the maximum number of Arrow IPC-encoded bytes sent
+// in a single proto.ArrowFrame message, kept comfortably under gRPC's
+// default 4MiB max message size.
+const arrowFrameChunkSize = 3 << 20
+
 const (
 	ErrPluginVersionNotSet     = bg.Error("plugin version not set")
 	ErrLaniakeaVersionMismatch = bg.Error("plugin requires a different version of laniakea")
@@ -58,6 +67,79 @@ func (c *DatasourceGRPCClient) StartRecord() (chan *proto.Frame, error) {
 	return frameChan, nil
 }
 
+// StartRecordArrow implements the Datasource interface method StartRecordArrow.
+// Chunked payloads sent by the plugin's SendFrame helper are reassembled
+// here so callers always receive one complete Arrow IPC stream per frame.
+// Chunks are buffered per Source so frames from different sources can
+// interleave on the stream without corrupting one another.
+func (c *DatasourceGRPCClient) StartRecordArrow() (chan *proto.ArrowFrame, error) {
+	stream, err := c.client.StartRecordArrow(context.Background(), &proto.Empty{})
+	if err != nil {
+		return nil, err
+	}
+	frameChan := make(chan *proto.ArrowFrame)
+	go func() {
+		defer close(frameChan)
+		bufs := make(map[string][]byte)
+		for {
+			chunk, err := stream.Recv()
+			if chunk == nil || err == io.EOF {
+				return
+			}
+			if err != nil {
+				break
+			}
+			bufs[chunk.Source] = append(bufs[chunk.Source], chunk.Payload...)
+			if !chunk.Final {
+				continue
+			}
+			frameChan <- &proto.ArrowFrame{
+				Source:    chunk.Source,
+				Timestamp: chunk.Timestamp,
+				Payload:   bufs[chunk.Source],
+				Final:     true,
+			}
+			delete(bufs, chunk.Source)
+		}
+	}()
+	return frameChan, nil
+}
+
+// CallResource implements the Datasource interface method CallResource.
+// Response chunks are reassembled into a single proto.ResourceResponse so
+// callers don't need to know the wire-level chunking.
+func (c *DatasourceGRPCClient) CallResource(req *proto.ResourceRequest) (chan *proto.ResourceResponse, error) {
+	stream, err := c.client.CallResource(context.Background(), req)
+	if err != nil {
+		return nil, err
+	}
+	respChan := make(chan *proto.ResourceResponse)
+	go func() {
+		defer close(respChan)
+		var resp *proto.ResourceResponse
+		for {
+			chunk, err := stream.Recv()
+			if chunk == nil || err == io.EOF {
+				return
+			}
+			if err != nil {
+				break
+			}
+			if resp == nil {
+				resp = &proto.ResourceResponse{Status: chunk.Status, Headers: chunk.Headers}
+			}
+			resp.Body = append(resp.Body, chunk.Body...)
+			if !chunk.Final {
+				continue
+			}
+			resp.Final = true
+			respChan <- resp
+			resp = nil
+		}
+	}()
+	return respChan, nil
+}
+
 // StopRecord implements the Datasource interface method StopRecord
 func (c *DatasourceGRPCClient) StopRecord() error {
 	_, err := c.client.StopRecord(context.Background(), &proto.Empty{})
@@ -94,6 +176,11 @@ func (c *DatasourceGRPCClient) GetVersion() (string, error) {
 	return resp.Version, nil
 }
 
+// GetManifest implements the Datasource interface method GetManifest
+func (c *DatasourceGRPCClient) GetManifest() (*proto.Manifest, error) {
+	return c.client.GetManifest(context.Background(), &proto.Empty{})
+}
+
 // StartRecord implements the Datasource gRPC server interface
 func (s *DatasourceGRPCServer) StartRecord(_ *proto.Empty, stream proto.Datasource_StartRecordServer) error {
 	frameChan, err := s.Impl.StartRecord()
@@ -118,6 +205,54 @@ func (s *DatasourceGRPCServer) StartRecord(_ *proto.Empty, stream proto.Datasour
 	}
 }
 
+// StartRecordArrow implements the Datasource gRPC server interface
+func (s *DatasourceGRPCServer) StartRecordArrow(_ *proto.Empty, stream proto.Datasource_StartRecordArrowServer) error {
+	frameChan, err := s.Impl.StartRecordArrow()
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case frame := <-frameChan:
+			if frame == nil {
+				return nil
+			}
+			if err := stream.Send(frame); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			if errors.Is(stream.Context().Err(), context.Canceled) {
+				return nil
+			}
+			return stream.Context().Err()
+		}
+	}
+}
+
+// CallResource implements the Datasource gRPC server interface
+func (s *DatasourceGRPCServer) CallResource(req *proto.ResourceRequest, stream proto.Datasource_CallResourceServer) error {
+	respChan, err := s.Impl.CallResource(req)
+	if err != nil {
+		return err
+	}
+	for {
+		select {
+		case resp := <-respChan:
+			if resp == nil {
+				return nil
+			}
+			if err := stream.Send(resp); err != nil {
+				return err
+			}
+		case <-stream.Context().Done():
+			if errors.Is(stream.Context().Err(), context.Canceled) {
+				return nil
+			}
+			return stream.Context().Err()
+		}
+	}
+}
+
 // StopRecord implements the Datasource gRPC server interface
 func (s *DatasourceGRPCServer) StopRecord(ctx context.Context, _ *proto.Empty) (*proto.Empty, error) {
 	err := s.Impl.StopRecord()
@@ -142,12 +277,100 @@ func (s *DatasourceGRPCServer) GetVersion(ctx context.Context, _ *proto.Empty) (
 	return &proto.VersionNumber{Version: v}, err
 }
 
+// GetManifest implements the Datasource gRPC server interface
+func (s *DatasourceGRPCServer) GetManifest(ctx context.Context, _ *proto.Empty) (*proto.Manifest, error) {
+	return s.Impl.GetManifest()
+}
+
 // DatasourceBase is a rough implementation of the Datasource interface
 // It implements the PushVersion and GetVersion functions for convenience
 type DatasourceBase struct {
 	version               string
 	laniVersionConstraint version.Constraints
 	laniVersion           string
+	resourceMux           *ResourceMux
+	signingKey            ed25519.PrivateKey
+	manifest              *PluginManifest
+	// Broker is the gRPC broker go-plugin handed this plugin at startup. It
+	// is populated automatically by DatasourcePlugin.GRPCServer; use
+	// DialPlugin instead of dialing it directly where possible.
+	Broker *plugin.GRPCBroker
+}
+
+// setBroker implements brokerSetter
+func (b *DatasourceBase) setBroker(broker *plugin.GRPCBroker) {
+	b.Broker = broker
+}
+
+// DialPlugin opens a direct gRPC connection to another loaded plugin named
+// name via Broker, without routing frames through the host process. This
+// enables compositional pipelines, e.g. a filter controller subscribing
+// directly to a sensor datasource's StartRecord stream.
+func (b *DatasourceBase) DialPlugin(name string) (Datasource, error) {
+	return dialPlugin(b.Broker, name)
+}
+
+// LoadSigningKey reads the ed25519 private key at path and stores it for
+// SignManifest/GetManifest to sign this plugin's PluginManifest with.
+func (b *DatasourceBase) LoadSigningKey(path string) error {
+	key, err := loadSigningKey(path)
+	if err != nil {
+		return err
+	}
+	b.signingKey = key
+	return nil
+}
+
+// SetManifest stores the PluginManifest that SignManifest/GetManifest sign
+// and return.
+func (b *DatasourceBase) SetManifest(m PluginManifest) {
+	b.manifest = &m
+}
+
+// SignManifest signs the PluginManifest set via SetManifest with the key
+// loaded via LoadSigningKey.
+func (b *DatasourceBase) SignManifest() (*proto.Manifest, error) {
+	if b.manifest == nil {
+		return nil, ErrManifestNotSet
+	}
+	if b.signingKey == nil {
+		return nil, ErrSigningKeyNotSet
+	}
+	return signManifest(b.manifest, b.signingKey), nil
+}
+
+// GetManifest implements the Datasource interface method GetManifest
+func (b *DatasourceBase) GetManifest() (*proto.Manifest, error) {
+	return b.SignManifest()
+}
+
+// HandleResource registers handler to serve CallResource requests matching
+// method and path, e.g. base.HandleResource("GET", "/config", handler).
+func (b *DatasourceBase) HandleResource(method, path string, handler ResourceHandler) {
+	if b.resourceMux == nil {
+		b.resourceMux = NewResourceMux()
+	}
+	b.resourceMux.Handle(method, path, handler)
+}
+
+// CallResource implements the Datasource interface method CallResource by
+// dispatching req through the ResourceMux populated via HandleResource, and
+// chunking the response across the returned channel. Embedders that need custom dispatch can
+// shadow this method.
+func (b *DatasourceBase) CallResource(req *proto.ResourceRequest) (chan *proto.ResourceResponse, error) {
+	if b.resourceMux == nil {
+		return nil, ErrResourceNotFound
+	}
+	resp, err := b.resourceMux.ServeResource(req)
+	if err != nil {
+		return nil, err
+	}
+	respChan := make(chan *proto.ResourceResponse)
+	go func() {
+		defer close(respChan)
+		sendResourceResponse(respChan, resp)
+	}()
+	return respChan, nil
 }
 
 // SetPluginVersion sets the plugin version string
@@ -178,6 +401,40 @@ func (b *DatasourceBase) GetVersion() (string, error) {
 	return b.version, nil
 }
 
+// NegotiatedProtocolVersion returns the wire protocol version this plugin
+// process negotiated with the host. Plugins served with ServeVersioned get
+// the highest version both sides advertised; plugins served with plain
+// plugin.Serve always get HandshakeConfig.ProtocolVersion.
+func (b *DatasourceBase) NegotiatedProtocolVersion() uint {
+	return negotiatedProtocolVersion
+}
+
+// SendFrame marshals f to its Arrow IPC representation and pushes it onto
+// out as one or more proto.ArrowFrame chunks, splitting large batches so no
+// single message exceeds gRPC's default max message size. Only the final
+// chunk has Final set; the client reassembles chunks sharing a Source
+// before handing a complete frame to callers.
+func (b *DatasourceBase) SendFrame(out chan<- *proto.ArrowFrame, f *data.Frame) error {
+	buf, err := f.MarshalArrow()
+	if err != nil {
+		return err
+	}
+	now := time.Now().UnixMilli()
+	for i := 0; i < len(buf); i += arrowFrameChunkSize {
+		end := i + arrowFrameChunkSize
+		if end > len(buf) {
+			end = len(buf)
+		}
+		out <- &proto.ArrowFrame{
+			Source:    f.Name,
+			Timestamp: now,
+			Payload:   buf[i:end],
+			Final:     end == len(buf),
+		}
+	}
+	return nil
+}
+
 // PushVersion sets the laniakea version atrribute
 func (b *DatasourceBase) PushVersion(versionNumber string) error {
 	laniV, err := version.NewVersion(versionNumber)
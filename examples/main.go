@@ -7,6 +7,7 @@ import (
 	"time"
 
 	sdk "github.com/SSSOC-CAN/laniakea-plugin-sdk"
+	"github.com/SSSOC-CAN/laniakea-plugin-sdk/data"
 	"github.com/SSSOC-CAN/laniakea-plugin-sdk/proto"
 	"github.com/hashicorp/go-plugin"
 )
@@ -53,6 +54,33 @@ func (e *DatasourceExample) StartRecord() (chan *proto.Frame, error) {
 	return frameChan, nil
 }
 
+// Implements the Datasource interface funciton StartRecordArrow
+func (e *DatasourceExample) StartRecordArrow() (chan *proto.ArrowFrame, error) {
+	frameChan := make(chan *proto.ArrowFrame)
+	e.Add(1)
+	go func() {
+		defer e.Done()
+		time.Sleep(1 * time.Second) // sleep for a second while laniakea sets up the plugin
+		for {
+			select {
+			case <-e.quitChan:
+				return
+			default:
+				frame := data.NewFrame(
+					pluginName,
+					data.NewField("time", data.TimestampNsType, []int64{time.Now().UnixNano()}),
+					data.NewField("sample", data.Float64Type, []float64{rand.Float64()}),
+				)
+				if err := e.SendFrame(frameChan, frame); err != nil {
+					log.Println(err)
+				}
+				time.Sleep(1 * time.Second)
+			}
+		}
+	}()
+	return frameChan, nil
+}
+
 // Implements the Datasource interface funciton StopRecord
 func (e *DatasourceExample) StopRecord() error {
 	e.quitChan <- struct{}{}
@@ -70,12 +98,17 @@ func main() {
 	impl := &DatasourceExample{}
 	impl.SetPluginVersion(pluginVersion)              // set the plugin version before serving
 	impl.SetVersionConstraints(laniVersionConstraint) // set required laniakea version before serving
-	plugin.Serve(&plugin.ServeConfig{
-		HandshakeConfig: sdk.HandshakeConfig,
-		Plugins: map[string]plugin.Plugin{
-			pluginName: &sdk.DatasourcePlugin{Impl: impl},
-		},
-		// A non-nil value here enables gRPC serving for this plugin...
-		GRPCServer: plugin.DefaultGRPCServer,
+	if err := impl.LoadSigningKey("signing.key"); err != nil {
+		log.Println(err)
+	}
+	impl.SetManifest(sdk.PluginManifest{
+		Name:                pluginName,
+		Version:             pluginVersion,
+		Publisher:           "SSSOC",
+		Capabilities:        []string{"StartRecord", "StartRecordArrow"},
+		RequiredLaniVersion: laniVersionConstraint,
+	})
+	sdk.Serve(map[string]plugin.Plugin{
+		pluginName: &sdk.DatasourcePlugin{Impl: impl},
 	})
 }
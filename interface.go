@@ -1,13 +1,17 @@
 /*
 Author: Paul Côté
 Last Change Author: Paul Côté
-Last Date Changed: 2022/07/07
+Last Date Changed: 2022/08/19
 */
 
 package laniakea_sdk
 
 import (
 	"context"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
 
 	"github.com/SSSOC-CAN/laniakea-plugin-sdk/proto"
 	"github.com/hashicorp/go-plugin"
@@ -20,23 +24,34 @@ var (
 		MagicCookieKey:   "LANIAKEA_PLUGIN_MAGIC_COOKIE",
 		MagicCookieValue: "a56e5daaa516e17d3d4b3d4685df9f8ca59c62c2d818cd5a7df13c039f134e16",
 	}
+	// negotiatedProtocolVersion holds the protocol version ServeVersioned
+	// picked for this plugin process. It is read by NegotiatedProtocolVersion
+	// on DatasourceBase/ControllerBase and defaults to HandshakeConfig.ProtocolVersion
+	// for plugins served with the plain, unversioned plugin.Serve.
+	negotiatedProtocolVersion = HandshakeConfig.ProtocolVersion
 )
 
 // Datasource interface describes an interface for plugins which will only produce streams of data
 type Datasource interface {
 	StartRecord() (chan *proto.Frame, error)
+	StartRecordArrow() (chan *proto.ArrowFrame, error)                             // Same as StartRecord but streams Arrow IPC-encoded record batches for high-throughput numeric data
+	CallResource(req *proto.ResourceRequest) (chan *proto.ResourceResponse, error) // Handles a plugin-defined HTTP-style resource request, e.g. routed through a ResourceMux
 	StopRecord() error
 	Stop() error
 	PushVersion(versionNumber string) error // This method pushes the version of Laniakea to the plugin. Plugin can then specify a minimum version of laniakea to run properly
 	GetVersion() (string, error)            // This method gets the version number from the plugin. Needed if plugins rely on other plugins and specific versions are needed
+	GetManifest() (*proto.Manifest, error)  // This method returns the plugin's signed PluginManifest so the host can verify its identity and capabilities before calling StartRecord
 }
 
 // Controller interface describes an interface for plugins which produce data but also act as controllers
 type Controller interface {
 	Stop() error
-	Command(*proto.Frame) (chan *proto.Frame, error)
-	PushVersion(versionNumber string) error // This method pushes the version of Laniakea to the plugin. Plugin can then specify a minimum version of laniakea to run properly
-	GetVersion() (string, error)            // This method gets the version number from the plugin. Needed if plugins rely on other plugins and specific versions are needed
+	Command(ctx context.Context, in <-chan *proto.Frame) (<-chan *proto.Frame, error) // Bidirectional: in carries setpoints/commands from the host, the returned channel carries telemetry/responses back, for the lifetime of the session; cancelling ctx tears the session down
+	CommandArrow(*proto.ArrowFrame) (chan *proto.ArrowFrame, error)                   // Sends one Arrow IPC-encoded frame and streams Arrow IPC-encoded record batches back, for high-throughput numeric data; unlike Command it is not a bidirectional session
+	CallResource(req *proto.ResourceRequest) (chan *proto.ResourceResponse, error)    // Handles a plugin-defined HTTP-style resource request, e.g. routed through a ResourceMux
+	PushVersion(versionNumber string) error                                           // This method pushes the version of Laniakea to the plugin. Plugin can then specify a minimum version of laniakea to run properly
+	GetVersion() (string, error)                                                      // This method gets the version number from the plugin. Needed if plugins rely on other plugins and specific versions are needed
+	GetManifest() (*proto.Manifest, error)                                            // This method returns the plugin's signed PluginManifest so the host can verify its identity and capabilities before calling Command
 }
 
 type DatasourcePlugin struct {
@@ -51,6 +66,9 @@ type ControllerPlugin struct {
 
 // GRPCServer implements the plugin.Plugin interface in the go-plugin package
 func (p *DatasourcePlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	if bs, ok := p.Impl.(brokerSetter); ok {
+		bs.setBroker(broker)
+	}
 	proto.RegisterDatasourceServer(s, &DatasourceGRPCServer{Impl: p.Impl})
 	return nil
 }
@@ -62,6 +80,9 @@ func (p *DatasourcePlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBr
 
 // GRPCServer implements the plugin.Plugin interface in the go-plugin package
 func (p *ControllerPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server) error {
+	if bs, ok := p.Impl.(brokerSetter); ok {
+		bs.setBroker(broker)
+	}
 	proto.RegisterControllerServer(s, &ControllerGRPCServer{Impl: p.Impl})
 	return nil
 }
@@ -70,3 +91,76 @@ func (p *ControllerPlugin) GRPCServer(broker *plugin.GRPCBroker, s *grpc.Server)
 func (p *ControllerPlugin) GRPCClient(ctx context.Context, broker *plugin.GRPCBroker, c *grpc.ClientConn) (interface{}, error) {
 	return &ControllerGRPCClient{client: proto.NewControllerClient(c)}, nil
 }
+
+// Serve wraps plugin.Serve with HandshakeConfig and AutoMTLS enabled, for
+// plugins that don't need protocol version negotiation (see ServeVersioned
+// for that). AutoMTLS has go-plugin generate a one-time client/server
+// certificate pair per launch and exchange them over the handshake, so a
+// binary that only knows the magic cookie still can't impersonate a plugin
+// on the gRPC channel itself.
+func Serve(plugins map[string]plugin.Plugin) {
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig: HandshakeConfig,
+		Plugins:         plugins,
+		GRPCServer:      plugin.DefaultGRPCServer,
+		AutoMTLS:        true,
+	})
+}
+
+// highestCommonVersion replicates go-plugin's own negotiation: the host
+// advertises every protocol version it understands, highest first, as a
+// comma-separated PLUGIN_PROTOCOL_VERSIONS env var; the first one also
+// present in sets is the version go-plugin's Serve will actually use.
+func highestCommonVersion(sets map[int]plugin.PluginSet) (int, bool) {
+	clientVersionsStr := os.Getenv("PLUGIN_PROTOCOL_VERSIONS")
+	if clientVersionsStr == "" {
+		return 0, false
+	}
+	var clientVersions []int
+	for _, s := range strings.Split(clientVersionsStr, ",") {
+		v, err := strconv.Atoi(s)
+		if err != nil {
+			continue
+		}
+		clientVersions = append(clientVersions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(clientVersions)))
+	for _, v := range clientVersions {
+		if _, ok := sets[v]; ok {
+			return v, true
+		}
+	}
+	return 0, false
+}
+
+// ServeVersioned wraps plugin.Serve for plugins that want to negotiate a
+// wire protocol version with the host instead of hardcoding one. sets maps
+// each protocol version this plugin binary supports to the plugin.PluginSet
+// it should register for that version. HandshakeConfig.ProtocolVersion is
+// set to the highest version in sets, so older hosts that only understand
+// go-plugin's VersionedPlugins negotiation still fall back correctly to a
+// version both sides share.
+//
+// The version the host actually negotiated down to is recorded before
+// plugin.Serve blocks, and is available afterwards to plugin code via
+// NegotiatedProtocolVersion() on DatasourceBase/ControllerBase.
+func ServeVersioned(sets map[int]plugin.PluginSet) {
+	var maxVersion int
+	for v := range sets {
+		if v > maxVersion {
+			maxVersion = v
+		}
+	}
+	negotiatedProtocolVersion = uint(maxVersion)
+	if v, ok := highestCommonVersion(sets); ok {
+		negotiatedProtocolVersion = uint(v)
+	}
+	handshake := HandshakeConfig
+	handshake.ProtocolVersion = uint(maxVersion)
+	plugin.Serve(&plugin.ServeConfig{
+		HandshakeConfig:  handshake,
+		VersionedPlugins: sets,
+		GRPCServer:       plugin.DefaultGRPCServer,
+		AutoMTLS:         true, // see Serve's doc comment
+	})
+}
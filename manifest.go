@@ -0,0 +1,96 @@
+/*
+Author: Paul Côté
+Last Change Author: Paul Côté
+Last Date Changed: 2022/10/14
+*/
+
+package laniakea_sdk
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/binary"
+	"encoding/hex"
+	"os"
+
+	"github.com/SSSOC-CAN/laniakea-plugin-sdk/proto"
+	bg "github.com/SSSOCPaulCote/blunderguard"
+)
+
+const (
+	ErrManifestNotSet    = bg.Error("plugin manifest not set")
+	ErrSigningKeyNotSet  = bg.Error("plugin signing key not loaded")
+	ErrInvalidSigningKey = bg.Error("signing key file is not a valid ed25519 private key")
+)
+
+// PluginManifest describes a plugin's identity and advertised capabilities.
+// It is signed with the ed25519 key loaded via LoadSigningKey each time
+// SignManifest or GetManifest is called; nothing is signed at build time.
+// The signature lets a host verify a plugin's identity and capabilities
+// before calling StartRecord/Command,
+// rather than discovering a mismatch through runtime errors like
+// ErrLaniakeaVersionMismatch.
+type PluginManifest struct {
+	Name                string
+	Version             string
+	Publisher           string
+	Capabilities        []string
+	RequiredLaniVersion string
+}
+
+// manifestSigningPayload produces the deterministic byte sequence a
+// manifest's signature is computed over. Every field is length-prefixed so
+// that shifting characters across a field boundary (e.g. Name="foo1",
+// Version=".0" vs Name="foo", Version="1.0") can't produce the same payload,
+// and therefore can't replay one manifest's signature against another.
+func manifestSigningPayload(m *PluginManifest, signingKeyFingerprint string) []byte {
+	var buf bytes.Buffer
+	writeField := func(s string) {
+		var length [4]byte
+		binary.BigEndian.PutUint32(length[:], uint32(len(s)))
+		buf.Write(length[:])
+		buf.WriteString(s)
+	}
+	writeField(m.Name)
+	writeField(m.Version)
+	writeField(m.Publisher)
+	writeField(m.RequiredLaniVersion)
+	writeField(signingKeyFingerprint)
+	var count [4]byte
+	binary.BigEndian.PutUint32(count[:], uint32(len(m.Capabilities)))
+	buf.Write(count[:])
+	for _, c := range m.Capabilities {
+		writeField(c)
+	}
+	return buf.Bytes()
+}
+
+// loadSigningKey reads an ed25519 private key from path, in the raw
+// 64-byte seed+public-key format ed25519.GenerateKey produces.
+func loadSigningKey(path string) (ed25519.PrivateKey, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if len(raw) != ed25519.PrivateKeySize {
+		return nil, ErrInvalidSigningKey
+	}
+	return ed25519.PrivateKey(raw), nil
+}
+
+// signManifest signs m with key and returns the wire-ready proto.Manifest,
+// including the signature and the signing key's fingerprint so hosts can
+// match it against an allowlist of trusted publishers.
+func signManifest(m *PluginManifest, key ed25519.PrivateKey) *proto.Manifest {
+	fingerprint := hex.EncodeToString(key.Public().(ed25519.PublicKey))
+	sig := ed25519.Sign(key, manifestSigningPayload(m, fingerprint))
+	return &proto.Manifest{
+		Name:                  m.Name,
+		Version:               m.Version,
+		Publisher:             m.Publisher,
+		Capabilities:          m.Capabilities,
+		RequiredLaniVersion:   m.RequiredLaniVersion,
+		SigningKeyFingerprint: fingerprint,
+		Signature:             sig,
+	}
+}
@@ -0,0 +1,95 @@
+/*
+Author: Paul Côté
+Last Change Author: Paul Côté
+Last Date Changed: 2022/09/23
+*/
+
+package laniakea_sdk
+
+import (
+	"sync"
+
+	"github.com/SSSOC-CAN/laniakea-plugin-sdk/proto"
+	bg "github.com/SSSOCPaulCote/blunderguard"
+)
+
+const (
+	ErrResourceNotFound = bg.Error("no resource handler registered for method/path")
+)
+
+// resourceChunkSize is the maximum number of response body bytes sent in a
+// single proto.ResourceResponse message, kept comfortably under gRPC's
+// default 4MiB max message size.
+const resourceChunkSize = 3 << 20
+
+// ResourceHandler handles a single CallResource request and returns the
+// response that should be sent back to the host.
+type ResourceHandler func(req *proto.ResourceRequest) (*proto.ResourceResponse, error)
+
+// ResourceMux is a minimal net/http-style router for CallResource requests,
+// keyed on method and path, so plugin authors can write
+// base.HandleResource("GET", "/config", handler) instead of implementing
+// the CallResource RPC themselves.
+type ResourceMux struct {
+	mu       sync.RWMutex
+	handlers map[string]ResourceHandler
+}
+
+// NewResourceMux constructs an empty ResourceMux.
+func NewResourceMux() *ResourceMux {
+	return &ResourceMux{handlers: make(map[string]ResourceHandler)}
+}
+
+// resourceKey builds the map key a (method, path) pair is registered under.
+func resourceKey(method, path string) string {
+	return method + " " + path
+}
+
+// Handle registers handler to serve requests matching method and path.
+func (m *ResourceMux) Handle(method, path string, handler ResourceHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.handlers[resourceKey(method, path)] = handler
+}
+
+// ServeResource dispatches req to the handler registered for its method and
+// path, returning ErrResourceNotFound if none matches.
+func (m *ResourceMux) ServeResource(req *proto.ResourceRequest) (*proto.ResourceResponse, error) {
+	m.mu.RLock()
+	handler, ok := m.handlers[resourceKey(req.Method, req.Path)]
+	m.mu.RUnlock()
+	if !ok {
+		return nil, ErrResourceNotFound
+	}
+	return handler(req)
+}
+
+// sendResourceResponse chunks resp's Body across out so no single message
+// exceeds gRPC's default max message size. Status and Headers are only set
+// on the first chunk; only the last chunk has Final set. resp may be nil,
+// e.g. a ResourceHandler legitimately returning an empty response, in which
+// case a single empty final chunk is sent.
+func sendResourceResponse(out chan<- *proto.ResourceResponse, resp *proto.ResourceResponse) {
+	if resp == nil {
+		resp = &proto.ResourceResponse{}
+	}
+	body := resp.Body
+	first := true
+	for first || len(body) > 0 {
+		end := len(body)
+		if end > resourceChunkSize {
+			end = resourceChunkSize
+		}
+		chunk := &proto.ResourceResponse{
+			Body:  body[:end],
+			Final: end == len(body),
+		}
+		if first {
+			chunk.Status = resp.Status
+			chunk.Headers = resp.Headers
+		}
+		out <- chunk
+		body = body[end:]
+		first = false
+	}
+}